@@ -3,23 +3,54 @@ package main
 import (
 	"kCache/lru"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// cache 是一个简单的缓存结构，使用 LRU 算法管理缓存项。
+// cacheStats 记录一个缓存分片的命中/未命中次数，供 Group.Stats 汇总展示。
+type cacheStats struct {
+	hits   int64
+	misses int64
+}
+
+func (s *cacheStats) recordHit() {
+	atomic.AddInt64(&s.hits, 1)
+}
+
+func (s *cacheStats) recordMiss() {
+	atomic.AddInt64(&s.misses, 1)
+}
+
+// cache 是一个简单的缓存结构，底层使用 lru.Cache 管理缓存项，
+// 淘汰策略（LRU/LFU）由 policy 决定。
 type cache struct {
 	mu         sync.Mutex // 互斥锁，用于保护并发访问
-	lru        *lru.Cache // LRU 缓存实例
+	lru        *lru.Cache // 底层缓存实例
 	cacheBytes int64      // 缓存的最大字节数限制
+	policy     lru.Policy // 淘汰策略，零值 lru.LRU 即原来的行为
+	stats      cacheStats // 命中/未命中计数
+}
+
+// ensureLRU 确保底层 lru.Cache 已经初始化并返回它，调用方必须持有 c.mu。
+func (c *cache) ensureLRU() *lru.Cache {
+	if c.lru == nil { // 如果底层缓存实例尚未初始化
+		c.lru = lru.NewWithPolicy(c.cacheBytes, c.policy, nil) // 根据缓存大小限制和策略初始化
+	}
+	return c.lru
 }
 
 // add 方法向缓存中添加一个键值对。
 func (c *cache) add(key string, value ByteView) {
 	c.mu.Lock()         // 加锁，确保并发安全
 	defer c.mu.Unlock() // 确保在方法结束时释放锁
-	if c.lru == nil {   // 如果 LRU 缓存实例尚未初始化
-		c.lru = lru.New(c.cacheBytes, nil) // 根据缓存大小限制初始化 LRU 缓存
-	}
-	c.lru.Add(key, value) // 将键值对添加到 LRU 缓存中
+	c.ensureLRU().Add(key, value)
+}
+
+// addWithTTL 和 add 一样，但条目会在 ttl 之后过期，Get 会把过期的条目当作未命中。
+func (c *cache) addWithTTL(key string, value ByteView, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureLRU().AddWithTTL(key, value, ttl)
 }
 
 // get 方法从缓存中获取一个键对应的值。
@@ -27,10 +58,30 @@ func (c *cache) get(key string) (value ByteView, ok bool) {
 	c.mu.Lock()         // 加锁，确保并发安全
 	defer c.mu.Unlock() // 确保在方法结束时释放锁
 	if c.lru == nil {   // 如果 LRU 缓存实例尚未初始化
+		c.stats.recordMiss()
 		return
 	}
 	if v, ok := c.lru.Get(key); ok { // 尝试从 LRU 缓存中获取键对应的值
+		c.stats.recordHit()
 		return v.(ByteView), ok // 如果存在，将值断言为 ByteView 类型并返回
 	}
+	c.stats.recordMiss()
 	return
 }
+
+// startJanitor 启动底层 lru.Cache 的后台清理 goroutine，按 interval 周期性清除
+// 已过期的 TTL 条目，而不是只靠 Get 时的惰性清理。
+func (c *cache) startJanitor(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureLRU().StartJanitor(interval)
+}
+
+// close 停止 startJanitor 启动的后台清理 goroutine（如果启动过的话）。
+func (c *cache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lru != nil {
+		c.lru.Close()
+	}
+}
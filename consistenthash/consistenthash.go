@@ -11,18 +11,22 @@ type Hash func(data []byte) uint32
 
 // Map 包含所有哈希后的键，并支持一致性哈希。
 type Map struct {
-	hash     Hash           // 哈希函数
-	replicas int            // 每个键的副本数量
-	keys     []int          // 排序后的哈希值列表
-	hashMap  map[int]string // 哈希值到键的映射
+	hash       Hash              // 哈希函数
+	replicas   int               // 每个节点默认的虚拟节点（副本）数量
+	keys       []int             // 排序后的哈希值列表
+	hashMap    map[int]string    // 哈希值到真实节点的映射，碰撞时指向 slotOwners 中最后一个存活的节点
+	nReplica   map[string]int    // 每个真实节点当前对应的虚拟节点数量，AddWeighted 会改写它
+	slotOwners map[int][]string // 每个哈希槽位上仍然存活的虚拟节点所属的真实节点，用于处理哈希碰撞
 }
 
 // New 创建一个新的 Map 实例。
 func New(replicas int, fn Hash) *Map {
 	m := &Map{
-		replicas: replicas,             // 设置副本数量
-		hash:     fn,                   // 设置哈希函数
-		hashMap:  make(map[int]string), // 初始化哈希映射
+		replicas:   replicas,             // 设置默认副本数量
+		hash:       fn,                   // 设置哈希函数
+		hashMap:    make(map[int]string), // 初始化哈希映射
+		nReplica:   make(map[string]int),
+		slotOwners: make(map[int][]string),
 	}
 	if m.hash == nil {
 		m.hash = crc32.ChecksumIEEE // 默认使用 CRC32 哈希函数
@@ -30,18 +34,84 @@ func New(replicas int, fn Hash) *Map {
 	return m
 }
 
-// Add 将多个键添加到一致性哈希环中。
+// Add 将多个键添加到一致性哈希环中，每个键使用默认的副本数量。
 func (m *Map) Add(keys ...string) {
 	for _, key := range keys {
-		for i := 0; i < m.replicas; i++ {
-			hash := int(m.hash([]byte(strconv.Itoa(i) + key))) // 为每个副本生成哈希值
-			m.keys = append(m.keys, hash)                      // 将哈希值加入列表
-			m.hashMap[hash] = key                              // 将哈希值与键关联
+		m.addReplicas(key, m.replicas)
+	}
+}
+
+// AddWeighted 将一个键添加到一致性哈希环中，副本数量为 replicas*weight，
+// weight 越大，这个节点在环上占据的虚拟节点就越多，分到的 key 也就越多。
+func (m *Map) AddWeighted(key string, weight int) {
+	m.addReplicas(key, m.replicas*weight)
+}
+
+// addReplicas 为 key 生成 n 个虚拟节点并加入哈希环。
+// key 如果已经在环上（重复的 Add，或者 AddWeighted 改变了权重），
+// 先把它原有的虚拟节点摘掉再重新加入，这样 slotOwners 才不会被同一个 key 重复计数，
+// 否则后续一次 Remove 永远无法让该 key 彻底从槽位上消失。
+func (m *Map) addReplicas(key string, n int) {
+	if _, ok := m.nReplica[key]; ok {
+		m.removeReplicas(key)
+	}
+	for i := 0; i < n; i++ {
+		hash := int(m.hash([]byte(strconv.Itoa(i) + key))) // 为每个副本生成哈希值
+		if len(m.slotOwners[hash]) == 0 {
+			m.keys = append(m.keys, hash) // 第一次出现该哈希值时才加入有序列表
 		}
+		m.slotOwners[hash] = append(m.slotOwners[hash], key) // 记录这个槽位新增的真实节点
+		m.hashMap[hash] = key                                // 将哈希值与真实节点关联
 	}
+	m.nReplica[key] = n
 	sort.Ints(m.keys) // 对哈希值进行排序
 }
 
+// Remove 从哈希环中移除 key 对应的所有虚拟节点。
+func (m *Map) Remove(key string) {
+	if _, ok := m.nReplica[key]; !ok {
+		return // 该 key 从未被添加过
+	}
+	m.removeReplicas(key)
+}
+
+// removeReplicas 把 key 从它对应的每个哈希槽位的所有者列表中摘除。
+// 如果某个哈希槽位和其他节点发生了碰撞，只有当所有者列表清空时才会真正清除这个槽位；
+// 如果槽位上还有别的节点存活，并且 hashMap 当前指向的正是被删除的 key，
+// 就把 hashMap 重新指向剩下的那个节点，否则 Get 会继续把请求路由到已经移除的节点上。
+func (m *Map) removeReplicas(key string) {
+	n := m.nReplica[key]
+	for i := 0; i < n; i++ {
+		hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+		owners := m.slotOwners[hash]
+		for j, owner := range owners {
+			if owner == key {
+				owners = append(owners[:j], owners[j+1:]...)
+				break
+			}
+		}
+		if len(owners) == 0 {
+			delete(m.slotOwners, hash)
+			delete(m.hashMap, hash)
+			m.removeKey(hash)
+			continue
+		}
+		m.slotOwners[hash] = owners
+		if m.hashMap[hash] == key {
+			m.hashMap[hash] = owners[len(owners)-1]
+		}
+	}
+	delete(m.nReplica, key)
+}
+
+// removeKey 从排序后的 keys 列表中删除一个哈希值。
+func (m *Map) removeKey(hash int) {
+	idx := sort.SearchInts(m.keys, hash)
+	if idx < len(m.keys) && m.keys[idx] == hash {
+		m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+	}
+}
+
 // Get 获取与给定键最接近的哈希值对应的键。
 func (m *Map) Get(key string) string {
 	if len(m.keys) == 0 {
@@ -0,0 +1,118 @@
+package consistenthash
+
+import (
+	"hash/crc32"
+	"strconv"
+	"testing"
+)
+
+// 用一个可预测的哈希函数，把字符串直接解析成数字，方便断言具体的路由结果。
+func newTestMap(replicas int) *Map {
+	return New(replicas, func(key []byte) uint32 {
+		n, _ := strconv.Atoi(string(key))
+		return uint32(n)
+	})
+}
+
+func TestGetRoutesToClosestPeer(t *testing.T) {
+	m := newTestMap(1)
+	m.Add("6", "4", "2") // 虚拟节点哈希值分别是 6、4、2
+
+	cases := map[string]string{
+		"2":  "2",
+		"11": "2", // 环形回绕到最小的节点
+		"23": "2",
+		"27": "2",
+	}
+	for key, want := range cases {
+		if got := m.Get(key); got != want {
+			t.Errorf("Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := newTestMap(1)
+	m.Add("6", "4", "2")
+	m.Remove("6")
+
+	if got := m.Get("7"); got == "6" {
+		t.Errorf("Get(7) still routes to removed peer %q", got)
+	}
+	if got, want := m.Get("5"), "2"; got != want {
+		t.Errorf("Get(5) = %q, want %q", got, want)
+	}
+}
+
+// TestAddIsIdempotent 覆盖重复 Add 同一个 key 的场景：一次 Remove 之后这个 key
+// 必须彻底从环上消失，而不是因为 slotOwners 被重复计数而继续存活。
+func TestAddIsIdempotent(t *testing.T) {
+	m := New(3, crc32.ChecksumIEEE)
+	m.Add("peerA")
+	m.Add("peerA") // 重复添加同一个节点，模拟重复的发现事件
+	m.Remove("peerA")
+
+	if len(m.keys) != 0 {
+		t.Fatalf("expected ring to be empty after one Remove following duplicate Add, got %d keys", len(m.keys))
+	}
+	if len(m.hashMap) != 0 {
+		t.Fatalf("expected hashMap to be empty after one Remove following duplicate Add, got %v", m.hashMap)
+	}
+}
+
+// TestRemoveOnCollidingSlotKeepsSurvivingOwner 覆盖两个不同节点的虚拟节点
+// 真正撞到同一个哈希槽位的场景：Add("A") 之后 Add("B") 撞到同一个槽位，
+// hashMap 这个槽位此时记的是最后写入的 "B"。Remove("B") 必须把 hashMap
+// 重新指向仍然存活的 "A"，而不是留着一个已经被删除的节点继续被 Get 路由到。
+func TestRemoveOnCollidingSlotKeepsSurvivingOwner(t *testing.T) {
+	collidingHash := func(key []byte) uint32 {
+		switch string(key) {
+		case "0A", "0B":
+			return 42
+		}
+		n, _ := strconv.Atoi(string(key))
+		return uint32(n)
+	}
+	m := New(1, collidingHash)
+	m.Add("A")
+	m.Add("B")
+
+	if got, want := m.hashMap[42], "B"; got != want {
+		t.Fatalf("hashMap[42] = %q, want %q before Remove", got, want)
+	}
+
+	m.Remove("B")
+
+	if got, want := m.slotOwners[42], []string{"A"}; len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("slotOwners[42] = %v, want %v", got, want)
+	}
+	if got, want := m.hashMap[42], "A"; got != want {
+		t.Fatalf("hashMap[42] = %q, want %q after removing the colliding peer", got, want)
+	}
+	if got, want := m.Get("42"), "A"; got != want {
+		t.Errorf("Get(42) = %q, want %q: still routing to a peer removed via a colliding slot", got, want)
+	}
+}
+
+// TestAddWeightedReplacesPreviousWeight 覆盖同一个 key 权重变化的场景：
+// 重新调用 AddWeighted 应当替换掉它之前的虚拟节点，而不是叠加。
+func TestAddWeightedReplacesPreviousWeight(t *testing.T) {
+	// 这里不能用 newTestMap：它把整个 key 解析成数字，而 "peerA" 这种非数字
+	// 节点名在每个副本上都会解析失败得到同一个 0，人为造成哈希碰撞。
+	m := New(2, crc32.ChecksumIEEE)
+	m.AddWeighted("peerA", 1)
+	firstCount := len(m.keys)
+
+	m.AddWeighted("peerA", 3)
+	if got, want := m.nReplica["peerA"], 2*3; got != want {
+		t.Fatalf("nReplica[peerA] = %d, want %d", got, want)
+	}
+	if len(m.keys) <= firstCount {
+		t.Fatalf("expected more virtual nodes after raising weight, got %d (was %d)", len(m.keys), firstCount)
+	}
+
+	m.Remove("peerA")
+	if len(m.keys) != 0 {
+		t.Fatalf("expected ring to be empty after removing the only peer, got %d keys", len(m.keys))
+	}
+}
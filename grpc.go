@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"kCache/grpcpool"
+	pb "kCache/proto"
+)
+
+// grpcPeerPicker 把 grpcpool.GRPCPool 适配成本包的 PeerPicker，
+// 让调用方可以在 HTTPPool 和 GRPCPool 两种传输之间自由选择。
+type grpcPeerPicker struct {
+	pool *grpcpool.GRPCPool
+}
+
+// NewGRPCPeerPicker 包装一个 GRPCPool，使其满足 PeerPicker 接口。
+func NewGRPCPeerPicker(pool *grpcpool.GRPCPool) PeerPicker {
+	return &grpcPeerPicker{pool: pool}
+}
+
+func (p *grpcPeerPicker) PickPeer(key string) (PeerGetter, bool) {
+	peer, ok := p.pool.PickPeer(key)
+	if !ok {
+		return nil, false
+	}
+	return &grpcPeerGetter{peer: peer}, true
+}
+
+// grpcPeerGetter 把 grpcpool.ErrNotFound 翻译成本包的 ErrNotFound，
+// 这样 Group.load 就可以用同一个哨兵错误识别“对端也没有这个 key”。
+type grpcPeerGetter struct {
+	peer grpcpool.Getter
+}
+
+func (g *grpcPeerGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	err := g.peer.Get(ctx, in, out)
+	if err == grpcpool.ErrNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// NewGRPCServer 创建一个 grpcpool.Server，把收到的请求转发给对应 Group 的 GetWithContext 方法，
+// 供调用方注册到自己的 grpc.Server 上（pb.RegisterGroupCacheServer）。
+func NewGRPCServer() *grpcpool.Server {
+	return grpcpool.NewServer(func(ctx context.Context, group, key string) ([]byte, error) {
+		g := GetGroup(group)
+		if g == nil {
+			return nil, fmt.Errorf("no such group: %s", group)
+		}
+		view, err := g.GetWithContext(ctx, key)
+		if err != nil {
+			if err == ErrNotFound {
+				return nil, grpcpool.ErrNotFound
+			}
+			return nil, err
+		}
+		return view.ByteSlice(), nil
+	})
+}
@@ -0,0 +1,141 @@
+// Package grpcpool 提供了一套基于 gRPC 的对等节点传输，
+// 作为 HTTPPool/httpGetter 的替代方案，供希望使用 gRPC 的调用方选择。
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"kCache/consistenthash"
+	pb "kCache/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// defaultReplicas 是一致性哈希环中每个真实节点默认对应的虚拟节点数量。
+const defaultReplicas = 50
+
+// ErrNotFound 由 Lookup 返回，表示 key 在源头就不存在；Server.Get 会把它
+// 转换成 codes.NotFound 状态，grpcGetter.Get 再把 codes.NotFound 还原成 ErrNotFound，
+// 从而让两端都能区分“暂时性错误”和“确实没有这个 key”。
+var ErrNotFound = errors.New("grpcpool: key not found")
+
+// Getter 的方法签名与 kCache 核心包里的 PeerGetter 一致（同样使用 kCache/proto 中
+// 的类型），因此持有 Getter 的调用方可以把它结构性地当作 PeerGetter 使用。
+type Getter interface {
+	Get(ctx context.Context, in *pb.Request, out *pb.Response) error
+}
+
+// grpcGetter 通过一条懒加载并复用的 gRPC 连接调用远程节点。
+type grpcGetter struct {
+	addr string
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+func (g *grpcGetter) client() (pb.GroupCacheClient, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn == nil {
+		conn, err := grpc.Dial(g.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		g.conn = conn
+	}
+	return pb.NewGroupCacheClient(g.conn), nil
+}
+
+// Get 通过 gRPC 向远程节点发起 Get 调用，并把结果写入 out。
+// ctx 被取消时，底层的 gRPC 调用会被中止并返回 ctx.Err()。
+func (g *grpcGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	client, err := g.client()
+	if err != nil {
+		return err
+	}
+	res, err := client.Get(ctx, in)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	out.Value = res.Value
+	return nil
+}
+
+var _ Getter = (*grpcGetter)(nil)
+
+// GRPCPool 维护一份一致性哈希环，根据 key 挑选拥有它的对等节点，
+// 是 HTTPPool 的 gRPC 版本。
+type GRPCPool struct {
+	self string
+
+	mu          sync.Mutex
+	peers       *consistenthash.Map
+	grpcGetters map[string]*grpcGetter
+}
+
+// NewGRPCPool 初始化一个 GRPCPool 实例，指定当前节点的地址。
+func NewGRPCPool(self string) *GRPCPool {
+	return &GRPCPool{self: self}
+}
+
+// Set 更新节点池中的对等节点列表。
+func (p *GRPCPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.peers = consistenthash.New(defaultReplicas, nil)
+	p.peers.Add(peers...)
+	p.grpcGetters = make(map[string]*grpcGetter, len(peers))
+	for _, peer := range peers {
+		p.grpcGetters[peer] = &grpcGetter{addr: peer}
+	}
+}
+
+// PickPeer 根据键选择一个对等节点。
+func (p *GRPCPool) PickPeer(key string) (Getter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		return p.grpcGetters[peer], true
+	}
+	return nil, false
+}
+
+// Lookup 由调用方提供，用于在服务端按组名和键查找值，
+// 解耦 Server 和具体的 Group/GetGroup 实现。返回 ErrNotFound 表示 key 确实不存在。
+// ctx 是这次 gRPC 调用的 Context，调用方应当把它一路传给 Group.GetWithContext，
+// 这样客户端断开连接时，服务端这边挂着的 singleflight 加载也能被及时放弃。
+type Lookup func(ctx context.Context, group, key string) ([]byte, error)
+
+// Server 实现 pb.GroupCacheServer，把收到的 gRPC 请求转发给 lookup。
+type Server struct {
+	pb.UnimplementedGroupCacheServer
+	lookup Lookup
+}
+
+// NewServer 创建一个 Server，lookup 通常是对 GetGroup(group).GetWithContext(ctx, key) 的包装。
+func NewServer(lookup Lookup) *Server {
+	return &Server{lookup: lookup}
+}
+
+// Get 实现 pb.GroupCacheServer。
+func (s *Server) Get(ctx context.Context, in *pb.Request) (*pb.Response, error) {
+	value, err := s.lookup(ctx, in.GetGroup(), in.GetKey())
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	return &pb.Response{Value: value}, nil
+}
@@ -0,0 +1,106 @@
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	pb "kCache/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startBufconnServer 把 lookup 包装成 Server，通过一条 bufconn 连接提供服务，
+// 返回一个已经连上它的 pb.GroupCacheClient 和清理函数。
+func startBufconnServer(t *testing.T, lookup Lookup) (pb.GroupCacheClient, func()) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := grpc.NewServer()
+	pb.RegisterGroupCacheServer(grpcSrv, NewServer(lookup))
+	go grpcSrv.Serve(lis)
+
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.DialContext() error = %v", err)
+	}
+
+	cleanup := func() {
+		conn.Close()
+		grpcSrv.Stop()
+		lis.Close()
+	}
+	return pb.NewGroupCacheClient(conn), cleanup
+}
+
+// TestServerTranslatesErrNotFoundToCodesNotFound 覆盖 Server.Get 把 lookup
+// 返回的 ErrNotFound 翻译成 codes.NotFound 状态这一侧。
+func TestServerTranslatesErrNotFoundToCodesNotFound(t *testing.T) {
+	client, cleanup := startBufconnServer(t, func(ctx context.Context, group, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	})
+	defer cleanup()
+
+	_, err := client.Get(context.Background(), &pb.Request{Group: "g", Key: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("client.Get() code = %v, want codes.NotFound", status.Code(err))
+	}
+}
+
+// TestGrpcGetterTranslatesCodesNotFoundToErrNotFound 覆盖 grpcGetter.Get 把
+// codes.NotFound 状态还原成 ErrNotFound 这一侧，两边合起来就是完整的往返。
+func TestGrpcGetterTranslatesCodesNotFoundToErrNotFound(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := grpc.NewServer()
+	pb.RegisterGroupCacheServer(grpcSrv, NewServer(func(ctx context.Context, group, key string) ([]byte, error) {
+		if key == "missing" {
+			return nil, ErrNotFound
+		}
+		return []byte("value-" + key), nil
+	}))
+	go grpcSrv.Serve(lis)
+	defer grpcSrv.Stop()
+	defer lis.Close()
+
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+	conn, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.DialContext() error = %v", err)
+	}
+	defer conn.Close()
+
+	getter := &grpcGetter{addr: "bufconn"}
+	getter.conn = conn // 复用上面已经建立的 bufconn 连接，跳过真实的 grpc.Dial
+
+	t.Run("found", func(t *testing.T) {
+		out := &pb.Response{}
+		if err := getter.Get(context.Background(), &pb.Request{Group: "g", Key: "k1"}, out); err != nil {
+			t.Fatalf("Get() error = %v, want nil", err)
+		}
+		if got, want := string(out.Value), "value-k1"; got != want {
+			t.Errorf("Value = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		out := &pb.Response{}
+		err := getter.Get(context.Background(), &pb.Request{Group: "g", Key: "missing"}, out)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+}
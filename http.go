@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"kCache/consistenthash"
+	pb "kCache/proto"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"sync"
+
+	"github.com/golang/protobuf/proto"
 )
 
 // 默认的基础路径和副本数量。
@@ -17,6 +21,12 @@ const (
 	defaultReplicas = 50            // 默认的副本数量
 )
 
+// notFoundHeader 在响应里标记这次 404 是 ErrNotFound（key 确认不存在，
+// 应当触发负缓存），而不是“没有这个 group”之类的其他 404。用专门的响应头
+// 而不是匹配响应体文本，这样任何一侧改动错误文案都不会悄悄破坏负缓存的传播，
+// 和 gRPC 传输用 codes.NotFound 传递同样的信息保持一致。
+const notFoundHeader = "X-Kcache-Not-Found"
+
 // httpGetter 是一个用于从远程 HTTP 服务器获取数据的结构体。
 type httpGetter struct {
 	baseURL string // 基础 URL，用于构建完整的请求地址
@@ -52,60 +62,90 @@ func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	p.Log("%s %s", r.Method, r.URL.Path) // 记录请求方法和路径
 
-	// 解析请求路径，格式应为 /<basePath>/<groupname>/<key>
-	parts := strings.SplitN(r.URL.Path[len(p.basePath):], "/", 2)
-	if len(parts) != 2 {
-		http.Error(w, "bad request", http.StatusBadRequest) // 如果路径格式不正确，返回400错误
+	// 请求体是一个序列化后的 pb.Request，其中携带了组名和键。
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	in := &pb.Request{}
+	if err := proto.Unmarshal(body, in); err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	groupName := parts[0] // 缓存组名
-	key := parts[1]       // 缓存键
-
-	group := GetGroup(groupName) // 根据组名获取缓存组
+	group := GetGroup(in.GetGroup()) // 根据组名获取缓存组
 	if group == nil {
-		http.Error(w, "no such group: "+groupName, http.StatusNotFound) // 如果组不存在，返回404错误
+		http.Error(w, "no such group: "+in.GetGroup(), http.StatusNotFound) // 如果组不存在，返回404错误
 		return
 	}
 
-	view, err := group.Get(key) // 从缓存组中获取键对应的值
+	view, err := group.GetWithContext(r.Context(), in.GetKey()) // 从缓存组中获取键对应的值
 	if err != nil {
+		if err == ErrNotFound {
+			w.Header().Set(notFoundHeader, "1")             // 标记这次 404 专指 key 不存在，供对端触发负缓存
+			http.Error(w, err.Error(), http.StatusNotFound) // key 确实不存在，返回404
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError) // 如果获取失败，返回500错误
 		return
 	}
 
+	out, err := proto.Marshal(&pb.Response{Value: view.ByteSlice()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/octet-stream") // 设置响应头，表示返回二进制数据
-	w.Write(view.ByteSlice())                                  // 将缓存值写入响应体
+	w.Write(out)                                               // 将序列化后的 pb.Response 写入响应体
 }
 
-// Get 方法通过 HTTP GET 请求从远程服务器获取指定键的值。
-func (h *httpGetter) Get(group string, key string) ([]byte, error) {
-	// 构建完整的请求 URL，包括对 group 和 key 的 URL 编码。
-	u := fmt.Sprintf(
-		"%v%v/%v",
-		h.baseURL,
-		url.QueryEscape(group),
-		url.QueryEscape(key),
-	)
-	// 发起 HTTP GET 请求。
-	res, err := http.Get(u)
+// Get 方法通过 HTTP POST 请求把序列化后的 pb.Request 发给远程服务器，
+// 并将响应体反序列化到 out 中。ctx 被取消时请求会被中止并返回 ctx.Err()。
+func (h *httpGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	body, err := proto.Marshal(in)
 	if err != nil {
-		return nil, err // 如果请求失败，返回错误
+		return fmt.Errorf("encoding request body: %v", err) // 如果编码失败，返回错误
 	}
-	defer res.Body.Close() // 确保响应体在函数返回时关闭
 
-	// 检查 HTTP 响应状态码。
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server returned: %v", res.Status) // 如果状态码不是 200 OK，返回错误
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
 	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// 发起 HTTP POST 请求。
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr // ctx 被取消或超时，返回 ctx.Err()
+		}
+		return err // 如果请求失败，返回错误
+	}
+	defer res.Body.Close() // 确保响应体在函数返回时关闭
 
 	// 读取响应体内容。
-	bytes, err := ioutil.ReadAll(res.Body)
+	resBody, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %v", err) // 如果读取失败，返回错误
+		return fmt.Errorf("reading response body: %v", err) // 如果读取失败，返回错误
 	}
 
-	return bytes, nil // 返回读取到的数据
+	// 检查 HTTP 响应状态码。404 且带有 notFoundHeader 时，说明对端已经确认
+	// 这个 key 不存在（而不是其他原因的 404，比如 group 不存在），
+	// 直接把哨兵错误传回去以便触发负缓存。
+	if res.StatusCode == http.StatusNotFound && res.Header.Get(notFoundHeader) != "" {
+		return ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned: %v", res.Status) // 如果状态码不是 200 OK，返回错误
+	}
+
+	if err := proto.Unmarshal(resBody, out); err != nil {
+		return fmt.Errorf("decoding response body: %v", err) // 如果解码失败，返回错误
+	}
+
+	return nil
 }
 
 // 确保 httpGetter 实现了 PeerGetter 接口。
@@ -123,6 +163,40 @@ func (p *HTTPPool) Set(peers ...string) {
 	}
 }
 
+// UpdatePeers 增量更新节点池中的对等节点列表：added 中的地址被加入哈希环，
+// removed 中的地址被移出。和 Set 不同，UpdatePeers 不会重建整个哈希环，
+// 未变化的节点仍然占据原来的槽位，它们的热缓存也就不会因为一次无关的扩缩容而被打散。
+func (p *HTTPPool) UpdatePeers(added, removed []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		p.peers = consistenthash.New(defaultReplicas, nil)
+		p.httpGetters = make(map[string]*httpGetter)
+	}
+	for _, peer := range removed {
+		p.peers.Remove(peer)
+		delete(p.httpGetters, peer)
+	}
+	for _, peer := range added {
+		p.peers.Add(peer)
+		p.httpGetters[peer] = &httpGetter{baseURL: peer + p.basePath}
+	}
+}
+
+// PeerDiscovery 是一个可选的节点发现抽象，用于对接 etcd/Consul 之类的 watcher，
+// 让对等节点列表可以在运行时变化，而不需要重启或重建整个哈希环。
+type PeerDiscovery interface {
+	// Watch 启动节点发现，每当对等节点列表发生变化就调用一次 onChange(added, removed)。
+	// Watch 应当在 ctx 被取消时停止监听并返回。
+	Watch(ctx context.Context, onChange func(added, removed []string)) error
+}
+
+// WatchPeers 使用给定的 PeerDiscovery 持续监听对等节点变化，并通过 UpdatePeers
+// 增量应用到哈希环上，调用方通常在独立的 goroutine 中运行它。
+func (p *HTTPPool) WatchPeers(ctx context.Context, d PeerDiscovery) error {
+	return d.Watch(ctx, p.UpdatePeers)
+}
+
 // PickPeer 方法根据键选择一个对等节点。
 func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
 	p.mu.Lock()
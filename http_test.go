@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "kCache/proto"
+)
+
+// TestHTTPPoolServeHTTPAndGetterRoundTrip 用 httptest.NewServer 起一个真实的
+// HTTPPool.ServeHTTP，再通过 httpGetter.Get 发起请求，覆盖 200、带
+// notFoundHeader 的 404、不带 notFoundHeader 的 404（group 不存在）、
+// 以及响应体损坏这几条路径。
+func TestHTTPPoolServeHTTPAndGetterRoundTrip(t *testing.T) {
+	groupName := uniqueGroupName(t)
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		if key == "missing" {
+			return nil, ErrNotFound
+		}
+		return []byte("value-" + key), nil
+	}}
+	NewGroup(groupName, 1<<20, getter)
+
+	pool := NewHTTPPool("http://example.invalid")
+	srv := httptest.NewServer(pool)
+	defer srv.Close()
+
+	t.Run("200 OK", func(t *testing.T) {
+		in := &pb.Request{Group: groupName, Key: "k1"}
+		out := &pb.Response{}
+		g := &httpGetter{baseURL: srv.URL + defaultBasePath}
+		if err := g.Get(context.Background(), in, out); err != nil {
+			t.Fatalf("Get() error = %v, want nil", err)
+		}
+		if got, want := string(out.Value), "value-k1"; got != want {
+			t.Errorf("Value = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("404 with notFoundHeader maps to ErrNotFound", func(t *testing.T) {
+		in := &pb.Request{Group: groupName, Key: "missing"}
+		out := &pb.Response{}
+		g := &httpGetter{baseURL: srv.URL + defaultBasePath}
+		if err := g.Get(context.Background(), in, out); err != ErrNotFound {
+			t.Fatalf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("404 without notFoundHeader is a plain error", func(t *testing.T) {
+		in := &pb.Request{Group: "no-such-group", Key: "k1"}
+		out := &pb.Response{}
+		g := &httpGetter{baseURL: srv.URL + defaultBasePath}
+		err := g.Get(context.Background(), in, out)
+		if err == nil || err == ErrNotFound {
+			t.Fatalf("Get() error = %v, want a plain (non-ErrNotFound) error", err)
+		}
+	})
+
+	t.Run("bad response body fails to decode", func(t *testing.T) {
+		badSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte{0xff, 0xfe, 0xfd}) // 不是合法的 pb.Response 序列化结果
+		}))
+		defer badSrv.Close()
+
+		in := &pb.Request{Group: groupName, Key: "k1"}
+		out := &pb.Response{}
+		g := &httpGetter{baseURL: badSrv.URL}
+		if err := g.Get(context.Background(), in, out); err == nil {
+			t.Fatal("Get() error = nil, want a decode error for a malformed body")
+		}
+	})
+}
@@ -1,12 +1,52 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"kCache/lru"
+	pb "kCache/proto"
 	"kCache/singleflight"
 	"log"
+	"math/rand"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrNotFound 由 Getter.Get（或 ContextGetter.GetWithContext）返回，
+// 表示这个 key 在源头就不存在，而不是加载过程中出现了暂时性错误。
+// Group 会把它在一段短 TTL 内负缓存起来，避免重复的 key 反复打到源头。
+var ErrNotFound = errors.New("kcache: key not found")
+
+// defaultNegativeTTL 是负缓存条目的默认存活时间。
+const defaultNegativeTTL = 5 * time.Second
+
+// negativeCacheBytes 是负缓存的字节预算，它只需要容纳一批 key 的存在性标记，
+// 不需要和 mainCache/hotCache 共享同一个预算。
+const negativeCacheBytes = 1 << 20
+
+// negativeJanitorInterval 是后台清理 negativeCache 过期墓碑的周期。
+// mainCache/hotCache 只通过 populateCache/hotCache.add 写入，从不带 TTL，
+// 所以不需要为它们启动同样的后台清理——惰性过期（Get 时检查）已经足够。
+const negativeJanitorInterval = defaultNegativeTTL
+
+// CachePolicy 是 lru.Policy 的别名，供调用方在创建 Group 时选择淘汰策略。
+type CachePolicy = lru.Policy
+
+// LRU 和 LFU 对应 mainCache/hotCache 可选的两种淘汰策略。
+const (
+	LRU = lru.LRU
+	LFU = lru.LFU
+)
+
+// hotCacheFraction 决定 hotCache 在总字节预算中所占的比例，其余归 mainCache。
+const hotCacheFraction = 8
+
+// hotCacheProbability 控制从对等节点拉取到的值写入 hotCache 的概率，
+// 取值为 1/hotCacheProbability，避免把每一次远程命中都提升为本地热点。
+const hotCacheProbability = 10
+
 // Getter 是一个接口，用于加载键对应的值。
 type Getter interface {
 	Get(key string) ([]byte, error)
@@ -20,15 +60,42 @@ func (f GetterFunc) Get(key string) ([]byte, error) {
 	return f(key)
 }
 
+// ContextGetter 是 Getter 的可选扩展。如果传给 NewGroup 的 Getter 同时实现了
+// ContextGetter，GetWithContext 会调用 GetWithContext 而不是 Get，
+// 这样取消信号才能一路传到真正发起加载的地方。
+type ContextGetter interface {
+	GetWithContext(ctx context.Context, key string) ([]byte, error)
+}
+
 // Group 是一个缓存命名空间，关联了加载数据的逻辑。
 type Group struct {
-	name      string
-	getter    Getter
-	mainCache cache
-	peers     PeerPicker
+	name          string
+	getter        Getter
+	mainCache     cache // 存储本节点拥有的数据
+	hotCache      cache // 存储从其他节点获取的热点数据副本，减少重复的跨节点 RPC
+	negativeCache cache // 存储确认不存在的 key 的墓碑标记，短 TTL 后自动失效
+	negativeTTL   time.Duration
+	peers         PeerPicker
 	// use singleflight.Group to make sure that
 	// each key is only fetched once
 	loader *singleflight.Group
+
+	peerLoads     int64 // 从远程节点成功加载的次数
+	peerErrors    int64 // 从远程节点加载失败的次数
+	localLoads    int64 // 回源到本地 Getter 成功的次数
+	localLoadErrs int64 // 回源到本地 Getter 失败的次数
+}
+
+// Stats 记录一个 Group 的缓存命中/未命中以及加载来源等统计信息，便于观测。
+type Stats struct {
+	MainCacheHits   int64
+	MainCacheMisses int64
+	HotCacheHits    int64
+	HotCacheMisses  int64
+	PeerLoads       int64
+	PeerErrors      int64
+	LocalLoads      int64
+	LocalLoadErrs   int64
 }
 
 var (
@@ -37,22 +104,47 @@ var (
 )
 
 // NewGroup 创建一个新的 Group 实例，并将其注册到全局 map 中。
+// mainCache 和 hotCache 都使用 LRU 淘汰策略。
 func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
+	return NewGroupWithPolicy(name, cacheBytes, getter, LRU)
+}
+
+// NewGroupWithPolicy 和 NewGroup 一样，但允许为 mainCache 和 hotCache 选择
+// LRU 或 LFU 淘汰策略。
+func NewGroupWithPolicy(name string, cacheBytes int64, getter Getter, policy CachePolicy) *Group {
 	if getter == nil {
 		panic("nil Getter")
 	}
 	mu.Lock()
 	defer mu.Unlock()
+	hotBytes := cacheBytes / hotCacheFraction
 	g := &Group{
-		name:      name,
-		getter:    getter,
-		mainCache: cache{cacheBytes: cacheBytes},
-		loader:    &singleflight.Group{},
+		name:          name,
+		getter:        getter,
+		mainCache:     cache{cacheBytes: cacheBytes - hotBytes, policy: policy},
+		hotCache:      cache{cacheBytes: hotBytes, policy: policy},
+		negativeCache: cache{cacheBytes: negativeCacheBytes, policy: policy},
+		negativeTTL:   defaultNegativeTTL,
+		loader:        &singleflight.Group{},
 	}
+	g.negativeCache.startJanitor(negativeJanitorInterval)
 	groups[name] = g
 	return g
 }
 
+// Close 停止这个 Group 的后台过期清理 goroutine（目前只有 negativeCache 用到
+// TTL，所以也只有它启动了 janitor），调用方不再需要这个 Group 时应当调用它，
+// 避免 goroutine 泄漏。Close 之后这个 Group 仍然可以继续使用，只是
+// negativeCache 的过期条目会退回到惰性清理（Get 时检查）。
+func (g *Group) Close() {
+	g.negativeCache.close()
+}
+
+// SetNegativeTTL 设置负缓存条目的存活时间，必须在第一次 Get 之前调用才能保证生效。
+func (g *Group) SetNegativeTTL(ttl time.Duration) {
+	g.negativeTTL = ttl
+}
+
 // GetGroup 返回之前通过 NewGroup 创建的 Group 实例，如果不存在则返回 nil。
 func GetGroup(name string) *Group {
 	mu.RLock()
@@ -63,24 +155,62 @@ func GetGroup(name string) *Group {
 
 // Get 从缓存中获取键对应的值，如果缓存中不存在，则通过 Getter 加载数据。
 func (g *Group) Get(key string) (ByteView, error) {
+	return g.GetWithContext(context.Background(), key)
+}
+
+// GetWithContext 和 Get 一样，但会把 ctx 传给加载回调（如果它实现了 ContextGetter）
+// 以及对等节点的 RPC 调用，调用方取消 ctx 就能提前放弃一次尚未完成的加载。
+func (g *Group) GetWithContext(ctx context.Context, key string) (ByteView, error) {
 	if key == "" {
 		return ByteView{}, fmt.Errorf("key is required")
 	}
 
-	if v, ok := g.mainCache.get(key); ok { // 尝试从缓存中获取数据
+	if v, ok := g.mainCache.get(key); ok { // 尝试从主缓存中获取数据
 		log.Println("[kCache] hit")
 		return v, nil
 	}
+	if v, ok := g.hotCache.get(key); ok { // 再尝试从热点缓存中获取数据
+		log.Println("[kCache] hot hit")
+		return v, nil
+	}
+	if _, ok := g.negativeCache.get(key); ok { // key 最近被确认过不存在，直接短路
+		return ByteView{}, ErrNotFound
+	}
 
-	return g.load(key) // 缓存中没有命中，加载数据
+	return g.load(ctx, key) // 缓存都未命中，加载数据
+}
+
+// Stats 返回该 Group 当前的缓存命中/未命中及加载来源计数，可用于监控面板或日志采集。
+func (g *Group) Stats() Stats {
+	return Stats{
+		MainCacheHits:   atomic.LoadInt64(&g.mainCache.stats.hits),
+		MainCacheMisses: atomic.LoadInt64(&g.mainCache.stats.misses),
+		HotCacheHits:    atomic.LoadInt64(&g.hotCache.stats.hits),
+		HotCacheMisses:  atomic.LoadInt64(&g.hotCache.stats.misses),
+		PeerLoads:       atomic.LoadInt64(&g.peerLoads),
+		PeerErrors:      atomic.LoadInt64(&g.peerErrors),
+		LocalLoads:      atomic.LoadInt64(&g.localLoads),
+		LocalLoadErrs:   atomic.LoadInt64(&g.localLoadErrs),
+	}
 }
 
-// getLocally 从本地加载数据，并将其填充到缓存中。
-func (g *Group) getLocally(key string) (ByteView, error) {
-	bytes, err := g.getter.Get(key)
+// getLocally 从本地加载数据，并将其填充到缓存中。如果 Getter 同时实现了
+// ContextGetter，ctx 会被传给它，这样取消信号能一路传到真正的回源调用。
+func (g *Group) getLocally(ctx context.Context, key string) (ByteView, error) {
+	var bytes []byte
+	var err error
+	if cg, ok := g.getter.(ContextGetter); ok {
+		bytes, err = cg.GetWithContext(ctx, key)
+	} else {
+		bytes, err = g.getter.Get(key)
+	}
 	if err != nil {
+		if err != ErrNotFound {
+			atomic.AddInt64(&g.localLoadErrs, 1)
+		}
 		return ByteView{}, err
 	}
+	atomic.AddInt64(&g.localLoads, 1)
 	value := ByteView{b: cloneBytes(bytes)} // 创建 ByteView 实例
 	g.populateCache(key, value)             // 将数据填充到缓存
 	return value, nil
@@ -100,40 +230,111 @@ func (g *Group) RegisterPeers(peers PeerPicker) {
 	g.peers = peers // 将 PeerPicker 实例绑定到缓存组
 }
 
-// load 方法尝试从本地或远程对等节点加载指定键的值。
-// load 方法尝试从本地或远程对等节点加载指定键的值。
-// 每个键的加载操作只执行一次，无论有多少并发调用者。
-func (g *Group) load(key string) (value ByteView, err error) {
-	// 使用 singleflight.Group 确保每个键的加载操作只执行一次
-	viewi, err := g.loader.Do(key, func() (interface{}, error) {
-		// 如果已注册 PeerPicker，尝试从远程对等节点获取数据
-		if g.peers != nil {
-			if peer, ok := g.peers.PickPeer(key); ok {
-				// 从远程对等节点获取数据
-				if value, err = g.getFromPeer(peer, key); err == nil {
-					return value, nil
-				}
-				log.Println("[kCache] Failed to get from peer", err)
+// fetch 根据 PeerPicker 把 key 路由给它的权威节点：如果注册了 PeerPicker 并且
+// 这个 key 归某个对等节点所有，就通过 RPC 向它获取；否则（未注册 PeerPicker，
+// PickPeer 没有选出对等节点，或者对等节点 RPC 失败）才回退到本地 Getter，
+// 由 getLocally 把结果写入 mainCache。load 通过这个逻辑加载；Refresh 不调用 fetch，
+// 而是自己先检查 PickPeer，再直接调用 getLocally。
+func (g *Group) fetch(ctx context.Context, key string) (ByteView, error) {
+	if g.peers != nil {
+		if peer, ok := g.peers.PickPeer(key); ok {
+			// 从远程对等节点获取数据
+			value, peerErr := g.getFromPeer(ctx, peer, key)
+			if peerErr == nil {
+				return value, nil
+			}
+			if peerErr == ErrNotFound {
+				// 对等节点是这个 key 的权威所有者，它确认不存在就不用再回源了
+				return ByteView{}, ErrNotFound
 			}
+			log.Println("[kCache] Failed to get from peer", peerErr)
 		}
+	}
 
-		// 如果远程获取失败或未注册 PeerPicker，则从本地加载
-		return g.getLocally(key)
+	// 未注册 PeerPicker、本节点就是权威所有者，或者对等节点 RPC 失败：回源到本地 Getter
+	return g.getLocally(ctx, key)
+}
+
+// load 方法尝试从本地或远程对等节点加载指定键的值。
+// 每个键的加载操作只执行一次，无论有多少并发调用者共享同一次结果。
+// 通过 DoChanContext 发起加载，这样当调用方的 ctx 被取消时可以立刻放弃等待，
+// 而不会阻塞它，同时一旦所有等待者都已放弃，真正发起的对等节点 RPC 也会被中止。
+func (g *Group) load(ctx context.Context, key string) (value ByteView, err error) {
+	ch, leave := g.loader.DoChanContext(key, func(loadCtx context.Context) (interface{}, error) {
+		return g.fetch(loadCtx, key)
 	})
 
-	// 如果加载成功，返回 ByteView 类型的结果
-	if err == nil {
-		return viewi.(ByteView), nil
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			if res.Err == ErrNotFound {
+				g.negativeCache.addWithTTL(key, ByteView{}, g.negativeTTL)
+			}
+			return ByteView{}, res.Err
+		}
+		return res.Val.(ByteView), nil
+	case <-ctx.Done():
+		// 我们不再关心结果了；如果我们是最后一个还在等待的调用者，
+		// loadCtx 会被取消，真正的对等节点 RPC 也就能随之中止。
+		leave()
+		return ByteView{}, ctx.Err()
 	}
-	return
 }
 
+// ErrRefreshNotOwner 由 Refresh 返回，表示调用方所在的节点不是 key 的权威所有者。
+// getFromPeer 发起的只是一次普通的对等节点 Get，会和对端自己的 mainCache 一样
+// 正常命中缓存，并不能强迫对端绕开它的缓存重新回源；所以 Refresh 没有办法对非
+// 权威节点的调用给出"已经从源头刷新"的保证，只能拒绝，而不是悄悄退化成一次
+// 可能返回旧值的普通读取。调用方应当只在 PickPeer(key) 指向本节点（或者根本
+// 没有注册 PeerPicker）时调用 Refresh。
+var ErrRefreshNotOwner = errors.New("kcache: Refresh must be called on the key's owning peer")
+
+// Refresh 强制从源重新加载 key，跳过 mainCache/hotCache/negativeCache，
+// 并用最新的值原子地替换 mainCache 中的旧值。并发的 Refresh(key) 调用会通过
+// singleflight 合并成一次真正的回源。如果注册了 PeerPicker 并且这个 key 归
+// 某个对等节点所有，Refresh 会返回 ErrRefreshNotOwner——调用方应当改为在
+// 拥有这个 key 的节点上调用 Refresh，而不是指望这里帮它转发。
+func (g *Group) Refresh(key string) (ByteView, error) {
+	if g.peers != nil {
+		if _, ok := g.peers.PickPeer(key); ok {
+			return ByteView{}, ErrRefreshNotOwner
+		}
+	}
+
+	viewi, err := g.loader.Do(refreshKeyPrefix+key, func() (interface{}, error) {
+		return g.getLocally(context.Background(), key)
+	})
+	if err != nil {
+		if err == ErrNotFound {
+			g.negativeCache.addWithTTL(key, ByteView{}, g.negativeTTL)
+		}
+		return ByteView{}, err
+	}
+	return viewi.(ByteView), nil
+}
+
+// refreshKeyPrefix 让 Refresh 使用和普通 Get 不同的 singleflight key，
+// 这样一次强制刷新不会和正在进行的、可能走对等节点的普通加载合并到一起。
+const refreshKeyPrefix = "refresh:"
+
 // getFromPeer 从指定的对等节点获取数据。
-func (g *Group) getFromPeer(peer PeerGetter, key string) (ByteView, error) {
+func (g *Group) getFromPeer(ctx context.Context, peer PeerGetter, key string) (ByteView, error) {
+	req := &pb.Request{Group: g.name, Key: key}
+	res := &pb.Response{}
 	// 调用对等节点的 Get 方法获取数据
-	bytes, err := peer.Get(g.name, key)
+	err := peer.Get(ctx, req, res)
 	if err != nil {
+		if err != ErrNotFound {
+			atomic.AddInt64(&g.peerErrors, 1)
+		}
 		return ByteView{}, err // 如果获取失败，返回错误
 	}
-	return ByteView{b: bytes}, nil // 包装数据并返回
+	atomic.AddInt64(&g.peerLoads, 1)
+	value := ByteView{b: res.Value}
+	// 以 1/hotCacheProbability 的概率把对等节点的数据提升为本地热点，
+	// 降低下次访问同一 key 时的跨节点 RPC 开销。
+	if rand.Intn(hotCacheProbability) == 0 {
+		g.hotCache.add(key, value)
+	}
+	return value, nil // 包装数据并返回
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "kCache/proto"
+)
+
+func TestNegativeCachingShortCircuitsGetLocally(t *testing.T) {
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+
+	if _, err := g.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("first Get() err = %v, want ErrNotFound", err)
+	}
+	if _, err := g.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Get() err = %v, want ErrNotFound", err)
+	}
+	if getter.callCount() != 1 {
+		t.Fatalf("Getter called %d times, want 1 (second Get should be served from negativeCache)", getter.callCount())
+	}
+}
+
+func TestNegativeCacheEntryExpires(t *testing.T) {
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		return nil, ErrNotFound
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+	g.SetNegativeTTL(10 * time.Millisecond)
+
+	if _, err := g.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("first Get() err = %v, want ErrNotFound", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := g.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Get() err = %v, want ErrNotFound", err)
+	}
+	if getter.callCount() != 2 {
+		t.Fatalf("Getter called %d times, want 2 (negative entry should have expired)", getter.callCount())
+	}
+}
+
+func TestGetWithContextReturnsOnCancellation(t *testing.T) {
+	getter := &blockingGetter{started: make(chan struct{})}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := g.GetWithContext(ctx, "k1")
+		errCh <- err
+	}()
+
+	select {
+	case <-getter.started:
+	case <-time.After(time.Second):
+		t.Fatal("load never reached the blocking Getter")
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("GetWithContext() err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetWithContext did not return promptly after ctx was canceled")
+	}
+}
+
+func TestRefreshRejectsNonOwner(t *testing.T) {
+	var localGetterCalled int32
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		atomic.AddInt32(&localGetterCalled, 1)
+		return nil, nil
+	}}
+	peer := &fakePeerGetter{fn: func(ctx context.Context, in *pb.Request, out *pb.Response) error {
+		out.Value = []byte("peer-value")
+		return nil
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+	g.RegisterPeers(&fakePeerPicker{peer: peer, ok: true})
+
+	if _, err := g.Refresh("k1"); !errors.Is(err, ErrRefreshNotOwner) {
+		t.Fatalf("Refresh() err = %v, want ErrRefreshNotOwner", err)
+	}
+	if atomic.LoadInt32(&localGetterCalled) != 0 {
+		t.Error("Refresh should not reach the local Getter when this node does not own the key")
+	}
+}
+
+func TestRefreshReloadsOwnedKeyAndUpdatesMainCache(t *testing.T) {
+	var version int32
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		return []byte(fmt.Sprintf("v%d", atomic.AddInt32(&version, 1))), nil
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+
+	v1, err := g.Get("k1")
+	if err != nil || v1.String() != "v1" {
+		t.Fatalf("Get() = (%v, %v), want (v1, nil)", v1, err)
+	}
+
+	v2, err := g.Refresh("k1")
+	if err != nil || v2.String() != "v2" {
+		t.Fatalf("Refresh() = (%v, %v), want (v2, nil)", v2, err)
+	}
+
+	v3, err := g.Get("k1")
+	if err != nil || v3.String() != "v2" {
+		t.Fatalf("Get() after Refresh = (%v, %v), want (v2, nil)", v3, err)
+	}
+	if getter.callCount() != 2 {
+		t.Fatalf("Getter called %d times, want 2 (Get should now be served from the refreshed mainCache entry)", getter.callCount())
+	}
+}
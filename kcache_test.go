@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "kCache/proto"
+)
+
+// countingGetter 是一个测试用的 Getter，记录调用次数，并用一个可配置的函数
+// 决定每次调用返回什么。
+type countingGetter struct {
+	mu    sync.Mutex
+	calls int
+	fn    func(callNo int, key string) ([]byte, error)
+}
+
+func (g *countingGetter) Get(key string) ([]byte, error) {
+	g.mu.Lock()
+	g.calls++
+	callNo := g.calls
+	g.mu.Unlock()
+	return g.fn(callNo, key)
+}
+
+func (g *countingGetter) callCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.calls
+}
+
+// blockingGetter 是一个同时实现 ContextGetter 的测试用 Getter（它的 Get 直接 panic），
+// GetWithContext 一直阻塞到 ctx 被取消，用于验证 GetWithContext 的取消传播。
+type blockingGetter struct {
+	started chan struct{}
+}
+
+func (g *blockingGetter) Get(key string) ([]byte, error) {
+	panic("blockingGetter.Get should not be called, GetWithContext should be preferred")
+}
+
+func (g *blockingGetter) GetWithContext(ctx context.Context, key string) ([]byte, error) {
+	close(g.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// fakePeerPicker 是一个测试用的 PeerPicker，总是（或从不）把 key 路由给同一个
+// PeerGetter。
+type fakePeerPicker struct {
+	peer PeerGetter
+	ok   bool
+}
+
+func (p *fakePeerPicker) PickPeer(key string) (PeerGetter, bool) {
+	if !p.ok {
+		return nil, false
+	}
+	return p.peer, true
+}
+
+// fakePeerGetter 是一个测试用的 PeerGetter，把请求转发给一个普通函数。
+type fakePeerGetter struct {
+	fn func(ctx context.Context, in *pb.Request, out *pb.Response) error
+}
+
+func (g *fakePeerGetter) Get(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	return g.fn(ctx, in, out)
+}
+
+// uniqueGroupName 避免不同测试复用同一个 group 名时互相踩踏全局 groups map。
+func uniqueGroupName(t *testing.T) string {
+	return fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+}
+
+func TestGetHitsMainCacheWithoutReloading(t *testing.T) {
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		return []byte("value-" + key), nil
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+
+	v1, err := g.Get("k1")
+	if err != nil || v1.String() != "value-k1" {
+		t.Fatalf("first Get() = (%v, %v), want (value-k1, nil)", v1, err)
+	}
+	v2, err := g.Get("k1")
+	if err != nil || v2.String() != "value-k1" {
+		t.Fatalf("second Get() = (%v, %v), want (value-k1, nil)", v2, err)
+	}
+	if getter.callCount() != 1 {
+		t.Fatalf("Getter called %d times, want 1 (second Get should hit mainCache)", getter.callCount())
+	}
+
+	stats := g.Stats()
+	if stats.MainCacheHits != 1 {
+		t.Errorf("Stats().MainCacheHits = %d, want 1", stats.MainCacheHits)
+	}
+	if stats.LocalLoads != 1 {
+		t.Errorf("Stats().LocalLoads = %d, want 1", stats.LocalLoads)
+	}
+}
+
+func TestGetHitsHotCache(t *testing.T) {
+	// fetch/getLocally 在 loader 内部的 goroutine 里执行，
+	// 所以这里只能记录"被调用过"，不能直接在闭包里 t.Fatal。
+	var localGetterCalled int32
+	getter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		atomic.AddInt32(&localGetterCalled, 1)
+		return nil, nil
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, getter)
+	g.hotCache.add("k1", ByteView{b: []byte("hot-value")})
+
+	v, err := g.Get("k1")
+	if err != nil || v.String() != "hot-value" {
+		t.Fatalf("Get() = (%v, %v), want (hot-value, nil)", v, err)
+	}
+	if atomic.LoadInt32(&localGetterCalled) != 0 {
+		t.Error("Getter should not be called when hotCache already has the value")
+	}
+
+	stats := g.Stats()
+	if stats.HotCacheHits != 1 {
+		t.Errorf("Stats().HotCacheHits = %d, want 1", stats.HotCacheHits)
+	}
+}
+
+func TestGetRoutesToOwningPeerInsteadOfLocalGetter(t *testing.T) {
+	var localGetterCalled int32
+	localGetter := &countingGetter{fn: func(callNo int, key string) ([]byte, error) {
+		atomic.AddInt32(&localGetterCalled, 1)
+		return nil, nil
+	}}
+	peer := &fakePeerGetter{fn: func(ctx context.Context, in *pb.Request, out *pb.Response) error {
+		out.Value = []byte("peer-value")
+		return nil
+	}}
+	g := NewGroup(uniqueGroupName(t), 1<<20, localGetter)
+	g.RegisterPeers(&fakePeerPicker{peer: peer, ok: true})
+
+	v, err := g.Get("k1")
+	if err != nil || v.String() != "peer-value" {
+		t.Fatalf("Get() = (%v, %v), want (peer-value, nil)", v, err)
+	}
+	if atomic.LoadInt32(&localGetterCalled) != 0 {
+		t.Error("local Getter should not be called when PickPeer owns the key")
+	}
+	if stats := g.Stats(); stats.PeerLoads != 1 {
+		t.Errorf("Stats().PeerLoads = %d, want 1", stats.PeerLoads)
+	}
+}
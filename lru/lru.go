@@ -3,108 +3,336 @@ package lru
 import (
 	"container/list"
 	"sync"
+	"time"
 )
 
-// Cache 是一个线程安全的LRU缓存。
-type Cache struct {
-	maxBytes  int64                         // 缓存的最大字节数限制
-	nbytes    int64                         // 当前缓存占用的字节数
-	ll        *list.List                    // 使用双向链表维护最近最少使用的顺序
-	cache     map[string]*list.Element      // 将键映射到链表中的元素
-	mu        sync.Mutex                    // 互斥锁，用于线程安全
-	OnEvicted func(key string, value Value) // 可选的回调函数，当缓存项被移除时调用
+// Vlue使用Len来计算它需要多少字节
+type Value interface {
+	Len() int
 }
 
+// Policy 决定缓存在字节超限时按照何种策略淘汰数据。
+type Policy int
+
+const (
+	LRU Policy = iota // 最近最少使用
+	LFU               // 最近最少使用频率，O(1) 实现
+)
+
 type entry struct {
-	key   string
-	value Value
+	key       string
+	value     Value
+	expiresAt time.Time // 零值表示永不过期
 }
 
-// Vlue使用Len来计算它需要多少字节
-type Value interface {
-	Len() int
+func (e *entry) expired() bool {
+	return !e.expiresAt.IsZero() && time.Now().After(e.expiresAt)
+}
+
+// evictor 是淘汰策略需要实现的内部接口，Cache 根据 Policy 选择其中一种实现。
+type evictor interface {
+	touch(key string)             // 键被访问或新增时调用，更新它在策略中的位置
+	remove(key string)            // 从策略的内部结构中移除一个键（不触发淘汰回调）
+	evict() (key string, ok bool) // 选出应当被淘汰的键
+}
+
+// Cache 是一个线程安全的缓存，支持 LRU/LFU 淘汰策略和按条目的 TTL 过期。
+type Cache struct {
+	maxBytes  int64 // 缓存的最大字节数限制
+	nbytes    int64 // 当前缓存占用的字节数
+	entries   map[string]*entry
+	evict     evictor
+	mu        sync.Mutex                    // 互斥锁，用于线程安全
+	OnEvicted func(key string, value Value) // 可选的回调函数，当缓存项被移除时调用
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
 }
 
+// New 创建一个使用 LRU 淘汰策略的 Cache，和原来的行为保持一致。
 func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
-	return &Cache{
+	return NewWithPolicy(maxBytes, LRU, onEvicted)
+}
+
+// NewWithPolicy 和 New 一样，但允许在 LRU 和 LFU 之间选择淘汰策略。
+func NewWithPolicy(maxBytes int64, policy Policy, onEvicted func(string, Value)) *Cache {
+	c := &Cache{
 		maxBytes:  maxBytes,
-		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
+		entries:   make(map[string]*entry),
 		OnEvicted: onEvicted,
 	}
+	if policy == LFU {
+		c.evict = newLFUEvictor()
+	} else {
+		c.evict = newLRUEvictor()
+	}
+	return c
 }
 
-// Get方法从缓存中获取指定键对应的值
+// Get方法从缓存中获取指定键对应的值，已过期的条目会被当作未命中处理并就地清除。
 func (c *Cache) Get(key string) (value Value, ok bool) {
-	c.mu.Lock()         // 加锁，保证线程安全
-	defer c.mu.Unlock() // 确保在函数返回时解锁
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// 查找键对应的双向链表节点
-	if ele, exists := c.cache[key]; exists {
-		c.ll.MoveToFront(ele) // 将最近访问的节点移动到队首
-		kv := ele.Value.(*entry)
-		return kv.value, true // 返回值并标记存在
+	e, exists := c.entries[key]
+	if !exists {
+		return nil, false
 	}
-	return nil, false
+	if e.expired() {
+		c.removeEntry(key, e)
+		return nil, false
+	}
+	c.evict.touch(key)
+	return e.value, true
 }
 
-// removeOldest是内部方法，用于移除最近最少访问的节点（队尾节点）
-func (c *Cache) removeOldest() {
-	ele := c.ll.Back() // 获取队尾节点
-	if ele != nil {
-		c.ll.Remove(ele) // 从链表中移除节点
-		kv := ele.Value.(*entry)
-		delete(c.cache, kv.key)                                // 从缓存字典中删除键
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len()) // 更新已使用字节数
-
-		// 如果有淘汰回调，则调用
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
-		}
-	}
+// Add方法将键值对添加到缓存中，不设置过期时间。
+func (c *Cache) Add(key string, value Value) {
+	c.add(key, value, time.Time{})
 }
 
-// RemoveOldest是公开方法，用于移除最近最少访问的节点
-func (c *Cache) RemoveOldest() {
-	c.mu.Lock() // 加锁
-	defer c.mu.Unlock()
-	c.removeOldest() // 调用内部方法
+// AddWithTTL 和 Add 一样，但条目会在 ttl 之后被视为过期。
+func (c *Cache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	c.add(key, value, time.Now().Add(ttl))
 }
 
-// Add方法将键值对添加到缓存中
-func (c *Cache) Add(key string, value Value) {
-	c.mu.Lock() // 加锁
+func (c *Cache) add(key string, value Value, expiresAt time.Time) {
+	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 如果键已存在，则更新值
-	if ele, exists := c.cache[key]; exists {
-		c.ll.MoveToFront(ele) // 将节点移动到队首
-		kv := ele.Value.(*entry)
-		c.nbytes += int64(value.Len()) - int64(kv.value.Len()) // 更新已使用字节数
-		kv.value = value                                       // 更新值
+	if e, exists := c.entries[key]; exists {
+		c.nbytes += int64(value.Len()) - int64(e.value.Len())
+		e.value = value
+		e.expiresAt = expiresAt
+		c.evict.touch(key)
 	} else {
-		// 键不存在，插入新节点
-		ele := c.ll.PushFront(&entry{key, value})        // 将新节点插入队首
-		c.cache[key] = ele                               // 将节点存入缓存字典
-		c.nbytes += int64(len(key)) + int64(value.Len()) // 更新已使用字节数
+		c.entries[key] = &entry{key: key, value: value, expiresAt: expiresAt}
+		c.evict.touch(key)
+		c.nbytes += int64(len(key)) + int64(value.Len())
 	}
 
-	// 如果设置了最大字节数并且超过限制，则移除最老的节点
 	for c.maxBytes != 0 && c.nbytes > c.maxBytes {
-		c.removeOldest()
+		c.evictOldest()
 	}
 }
 
+// evictOldest是内部方法，委托给当前的淘汰策略选出一个键并移除它。
+func (c *Cache) evictOldest() {
+	key, ok := c.evict.evict()
+	if !ok {
+		return
+	}
+	e, exists := c.entries[key]
+	if !exists {
+		return
+	}
+	delete(c.entries, key)
+	c.nbytes -= int64(len(key)) + int64(e.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(key, e.value)
+	}
+}
+
+// removeEntry 从缓存和淘汰策略中移除一个已知条目，用于过期清理（而不是字节压力淘汰）。
+func (c *Cache) removeEntry(key string, e *entry) {
+	c.evict.remove(key)
+	delete(c.entries, key)
+	c.nbytes -= int64(len(key)) + int64(e.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(key, e.value)
+	}
+}
+
+// RemoveOldest是公开方法，用于移除当前淘汰策略下最应被淘汰的条目。
+func (c *Cache) RemoveOldest() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictOldest()
+}
+
 func (c *Cache) Len() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.ll.Len()
+	return len(c.entries)
 }
 
 func (c *Cache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	for c.ll.Len() > 0 {
-		c.removeOldest()
+	for key, e := range c.entries {
+		c.evict.remove(key)
+		delete(c.entries, key)
+		if c.OnEvicted != nil {
+			c.OnEvicted(key, e.value)
+		}
+	}
+	c.nbytes = 0
+}
+
+// sweepExpired 清除所有已过期的条目，由 StartJanitor 启动的后台 goroutine 周期性调用。
+func (c *Cache) sweepExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, e := range c.entries {
+		if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+			c.removeEntry(key, e)
+		}
+	}
+}
+
+// StartJanitor 启动一个后台 goroutine，每隔 interval 清理一次已过期的条目，
+// 直到 Close 被调用。重复调用会启动多个 goroutine，调用方应当只启动一次。
+func (c *Cache) StartJanitor(interval time.Duration) {
+	c.closeCh = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sweepExpired()
+			case <-c.closeCh:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止 StartJanitor 启动的后台清理 goroutine（如果有的话）。
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		if c.closeCh != nil {
+			close(c.closeCh)
+		}
+	})
+}
+
+// lruEvictor 用双向链表维护最近最少使用的顺序。
+type lruEvictor struct {
+	ll    *list.List
+	elems map[string]*list.Element
+}
+
+func newLRUEvictor() *lruEvictor {
+	return &lruEvictor{ll: list.New(), elems: make(map[string]*list.Element)}
+}
+
+func (e *lruEvictor) touch(key string) {
+	if ele, ok := e.elems[key]; ok {
+		e.ll.MoveToFront(ele)
+		return
+	}
+	e.elems[key] = e.ll.PushFront(key)
+}
+
+func (e *lruEvictor) remove(key string) {
+	if ele, ok := e.elems[key]; ok {
+		e.ll.Remove(ele)
+		delete(e.elems, key)
+	}
+}
+
+func (e *lruEvictor) evict() (string, bool) {
+	ele := e.ll.Back()
+	if ele == nil {
+		return "", false
+	}
+	key := ele.Value.(string)
+	e.ll.Remove(ele)
+	delete(e.elems, key)
+	return key, true
+}
+
+// lfuNode 是 lfuEvictor 链表中的节点，记录它当前所在的访问频率。
+type lfuNode struct {
+	key  string
+	freq int
+}
+
+// lfuEvictor 实现 O(1) 的 LFU 淘汰：map key->node 定位节点，
+// map freq->双向链表把相同频率的节点串在一起，minFreq 记录当前最小的非空频率。
+type lfuEvictor struct {
+	nodes   map[string]*list.Element
+	freqs   map[int]*list.List
+	minFreq int
+}
+
+func newLFUEvictor() *lfuEvictor {
+	return &lfuEvictor{
+		nodes: make(map[string]*list.Element),
+		freqs: make(map[int]*list.List),
+	}
+}
+
+func (e *lfuEvictor) bucket(freq int) *list.List {
+	fl, ok := e.freqs[freq]
+	if !ok {
+		fl = list.New()
+		e.freqs[freq] = fl
+	}
+	return fl
+}
+
+func (e *lfuEvictor) touch(key string) {
+	if ele, ok := e.nodes[key]; ok {
+		n := ele.Value.(*lfuNode)
+		oldFreq := n.freq
+		e.freqs[oldFreq].Remove(ele)
+		if e.freqs[oldFreq].Len() == 0 {
+			delete(e.freqs, oldFreq)
+			if e.minFreq == oldFreq {
+				e.minFreq++
+			}
+		}
+		n.freq++
+		e.nodes[key] = e.bucket(n.freq).PushFront(n)
+		return
+	}
+	e.nodes[key] = e.bucket(1).PushFront(&lfuNode{key: key, freq: 1})
+	e.minFreq = 1
+}
+
+func (e *lfuEvictor) remove(key string) {
+	ele, ok := e.nodes[key]
+	if !ok {
+		return
+	}
+	n := ele.Value.(*lfuNode)
+	fl := e.freqs[n.freq]
+	fl.Remove(ele)
+	if fl.Len() == 0 {
+		delete(e.freqs, n.freq)
+	}
+	delete(e.nodes, key)
+}
+
+func (e *lfuEvictor) evict() (string, bool) {
+	fl, ok := e.freqs[e.minFreq]
+	if !ok || fl.Len() == 0 {
+		// minFreq 失效了，通常是因为 TTL 清理之类的非淘汰路径移除了节点，
+		// 重新扫描出当前真正的最小频率。
+		e.minFreq = 0
+		for freq, l := range e.freqs {
+			if l.Len() == 0 {
+				continue
+			}
+			if e.minFreq == 0 || freq < e.minFreq {
+				e.minFreq = freq
+			}
+		}
+		fl, ok = e.freqs[e.minFreq]
+		if !ok || fl.Len() == 0 {
+			return "", false
+		}
+	}
+
+	ele := fl.Back()
+	n := ele.Value.(*lfuNode)
+	fl.Remove(ele)
+	if fl.Len() == 0 {
+		delete(e.freqs, n.freq)
 	}
+	delete(e.nodes, n.key)
+	return n.key, true
 }
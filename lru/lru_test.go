@@ -0,0 +1,106 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+type testValue string
+
+func (v testValue) Len() int {
+	return len(v)
+}
+
+func TestGetExpired(t *testing.T) {
+	c := New(0, nil)
+	c.AddWithTTL("k1", testValue("v1"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("k1"); ok {
+		t.Fatal("expected expired entry to be treated as a miss")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected expired entry to be purged on access, Len() = %d", c.Len())
+	}
+}
+
+func TestGetNotExpired(t *testing.T) {
+	c := New(0, nil)
+	c.AddWithTTL("k1", testValue("v1"), time.Hour)
+
+	if _, ok := c.Get("k1"); !ok {
+		t.Fatal("expected entry with a future TTL to still be present")
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewWithPolicy(int64(len("k1")+len("v1")+len("k2")+len("v2")), LRU, nil)
+	c.Add("k1", testValue("v1"))
+	c.Add("k2", testValue("v2"))
+	c.Get("k1")                  // 访问 k1，让它比 k2 更新
+	c.Add("k3", testValue("v3")) // 超出字节预算，应当淘汰最久未被访问的 k2
+
+	if _, ok := c.Get("k2"); ok {
+		t.Fatal("expected k2 to be evicted as the least recently used entry")
+	}
+	if _, ok := c.Get("k1"); !ok {
+		t.Fatal("expected k1 to survive eviction since it was touched more recently")
+	}
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := NewWithPolicy(int64(len("k1")+len("v1")+len("k2")+len("v2")), LFU, nil)
+	c.Add("k1", testValue("v1"))
+	c.Add("k2", testValue("v2"))
+	c.Get("k1") // k1 共被访问三次（Add 一次加两次 Get），k2 只有 Add 那一次
+	c.Get("k1")
+	c.Add("k3", testValue("v3")) // 超出预算，应当淘汰访问频率最低的 k2
+
+	if _, ok := c.Get("k2"); ok {
+		t.Fatal("expected k2 to be evicted as the least frequently used entry")
+	}
+	if _, ok := c.Get("k1"); !ok {
+		t.Fatal("expected k1 to survive eviction since it was accessed more often")
+	}
+}
+
+func TestStartJanitorSweepsExpiredEntries(t *testing.T) {
+	c := New(0, nil)
+	c.AddWithTTL("k1", testValue("v1"), 5*time.Millisecond)
+	c.StartJanitor(5 * time.Millisecond)
+	defer c.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	c.mu.Lock()
+	_, exists := c.entries["k1"]
+	c.mu.Unlock()
+	if exists {
+		t.Fatal("expected the janitor to sweep the expired entry in the background, without a Get call")
+	}
+}
+
+func TestCloseStopsJanitorAndIsIdempotent(t *testing.T) {
+	c := New(0, nil)
+	c.StartJanitor(time.Millisecond)
+	c.Close()
+	c.Close() // 重复调用不应 panic（closeOnce 保证只真正关闭一次）
+}
+
+func TestCloseWithoutJanitorIsSafe(t *testing.T) {
+	c := New(0, nil)
+	c.Close() // 从未调用过 StartJanitor 时 Close 也不应 panic
+}
+
+func TestOnEvictedCalledOnByteEviction(t *testing.T) {
+	var evictedKey string
+	c := NewWithPolicy(int64(len("k1")+len("v1")), LRU, func(key string, value Value) {
+		evictedKey = key
+	})
+	c.Add("k1", testValue("v1"))
+	c.Add("k2", testValue("v2")) // 超出预算，k1 应当被淘汰并触发回调
+
+	if evictedKey != "k1" {
+		t.Fatalf("OnEvicted called with key %q, want %q", evictedKey, "k1")
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import pb "kCache/proto"
+import (
+	"context"
+
+	pb "kCache/proto"
+)
 
 // PeerPicker 是一个接口，用于定位拥有特定键的对等节点（peer）。
 // 实现该接口的类型需要提供一个方法来选择对等节点。
@@ -14,7 +18,8 @@ type PeerPicker interface {
 // PeerGetter 是一个接口，表示对等节点（peer）的功能。
 // 实现该接口的类型需要提供一个方法来从对等节点获取数据。
 type PeerGetter interface {
-	// Get 方法从对等节点获取指定分组和键的值。
-	// 如果成功获取数据，返回字节切片；否则返回错误。
-	Get(in *pb.Request, out *pb.Response) ([]byte, error)
+	// Get 方法从对等节点获取 in 指定分组和键的值，并将结果写入 out。
+	// ctx 被取消时，实现应当尽快放弃这次调用并返回 ctx.Err()。
+	// HTTP 和 gRPC 两种传输各自实现这个接口。
+	Get(ctx context.Context, in *pb.Request, out *pb.Response) error
 }
@@ -1,12 +1,66 @@
 package singleflight
 
-import "sync"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
 
-// call 用于存储函数调用的结果。
+// panicError 包装 fn 内部发生的 panic 及其调用栈，这样每一个共享这次调用结果的
+// 等待者都能在自己的 goroutine 里重新看到原始的 panic，而不是被当成普通 error 吞掉。
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+func newPanicError(v interface{}) error {
+	stack := debug.Stack()
+	// 第一行是 debug.Stack 自己的调用帧，没什么用，去掉它。
+	if line := bytes.IndexByte(stack, '\n'); line >= 0 {
+		stack = stack[line+1:]
+	}
+	return &panicError{value: v, stack: stack}
+}
+
+// Result 是 DoChan/DoChanContext 通过 channel 返回的调用结果。
+type Result struct {
+	Val    interface{}
+	Err    error
+	Shared bool // 这个结果是否被多于一个调用者共享
+}
+
+// call 代表某个 key 正在进行（或刚刚结束）的一次 fn 调用。
 type call struct {
-	wg  sync.WaitGroup // 用于同步等待函数执行完成
-	val interface{}    // 函数返回的值
-	err error          // 函数执行过程中可能发生的错误
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+
+	dups  int             // 除发起者外还有多少调用者在共享这次结果，仅用于 Result.Shared
+	chans []chan<- Result // DoChan/DoChanContext 的等待者
+
+	ctx    context.Context    // 传给 fn 的 Context
+	cancel context.CancelFunc // 所有等待者都调用 leave 后，用它中止 fn 里仍在进行的工作
+
+	refMu sync.Mutex
+	refs  int // 还没有调用 leave 的等待者数量
+}
+
+// leave 让一个调用者声明自己不再关心这次调用的结果。当所有共享者都调用过 leave，
+// 传给 fn 的 Context 会被取消——如果 fn 这时已经返回，取消是无害的空操作。
+func (c *call) leave() {
+	c.refMu.Lock()
+	c.refs--
+	remaining := c.refs
+	c.refMu.Unlock()
+	if remaining <= 0 {
+		c.cancel()
+	}
 }
 
 // Group 是一个并发控制结构体，确保同一个 key 的函数只执行一次。
@@ -15,29 +69,130 @@ type Group struct {
 	m  map[string]*call // 存储 key 和对应的 call 实例
 }
 
-// Do 方法确保同一个 key 的函数 fn 只会被执行一次。
+// Do 方法确保同一个 key 的函数 fn 只会被执行一次，所有并发调用者共享同一个结果。
+// Do 是同步调用，fn 内部发生的 panic 会在每一个等待者自己调用 Do 的那个 goroutine
+// 里被重新 panic，和这个 goroutine 自己 panic 的效果完全一样（外层的 recover 能捕获它）。
+//
+// DoChan/DoChanContext 不提供同样的保证：它们立即返回，结果总是通过 channel
+// 异步交付，没有一个“调用 fn 的 goroutine”可以代替调用方重新 panic——如果在那里
+// 强行 panic，炸的会是内部的转发 goroutine 而不是调用方自己的调用栈，调用方也就
+// 没有办法用自己的 recover 接住它。所以 DoChan/DoChanContext 只是把 panic 包装成
+// 一个普通的 Result.Err 交给调用方，调用方如果关心这种情况，需要自己在收到
+// 结果后决定如何处理，而不能依赖这里帮它重新抛出。
 func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
 	g.mu.Lock()
 	if g.m == nil {
-		g.m = make(map[string]*call) // 初始化 map
+		g.m = make(map[string]*call)
+	}
+	if c, ok := g.m[key]; ok {
+		c.dups++
+		c.refMu.Lock()
+		c.refs++
+		c.refMu.Unlock()
+		g.mu.Unlock()
+		c.wg.Wait()
+		c.leave() // 等到自己真正拿到结果才放弃这一份引用，中途不会被其他调用者的提前 leave 连累取消
+		if e, ok := c.err.(*panicError); ok {
+			panic(e)
+		}
+		return c.val, c.err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &call{ctx: ctx, cancel: cancel, refs: 1}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	g.doCall(c, key, func(ctx context.Context) (interface{}, error) { return fn() })
+
+	if e, ok := c.err.(*panicError); ok {
+		panic(e)
+	}
+	return c.val, c.err
+}
+
+// DoChan 和 Do 类似，但立即返回一个 channel，只会被写入一次，调用方可以对它
+// 和其他事件（例如自己的 ctx.Done()）做 select，从而在不阻塞的情况下等待结果。
+// fn 内部发生的 panic 不会在这里被重新 panic，而是作为一个普通 error 装进
+// Result.Err（见 Do 的文档注释，两者在 panic 处理上有意不同）。
+//
+// DoChan 自己没有办法把 DoChanContext 返回的 leave 交给调用方，所以它在内部
+// 用一个转发 goroutine 代为持有这份引用：一旦结果送达（也就是这次等待已经
+// 结束），就立即 leave，避免这份引用永远挂在那里、阻止其他共享者提前取消。
+func (g *Group) DoChan(key string, fn func() (interface{}, error)) <-chan Result {
+	inner, leave := g.DoChanContext(key, func(context.Context) (interface{}, error) { return fn() })
+	outer := make(chan Result, 1)
+	go func() {
+		r := <-inner
+		leave()
+		outer <- r
+	}()
+	return outer
+}
+
+// DoChanContext 和 DoChan 类似，但 fn 会收到一个 Context，调用方通过返回的 leave
+// 函数声明自己不再等待结果；当共享这次调用的所有调用者都调用过 leave，
+// 这个 Context 就会被取消，好让 fn 里真正发起的 RPC 随之中止，
+// 而不是为一个已经没有人关心结果的调用继续占用资源。
+// 和 DoChan 一样，fn 内部发生的 panic 不会被重新 panic，只会作为普通 error
+// 出现在 Result.Err 里。
+func (g *Group) DoChanContext(key string, fn func(ctx context.Context) (interface{}, error)) (<-chan Result, func()) {
+	ch := make(chan Result, 1)
+
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
 	}
 	if c, ok := g.m[key]; ok {
-		g.mu.Unlock()       // 如果 key 已存在，释放锁
-		c.wg.Wait()         // 等待函数执行完成
-		return c.val, c.err // 返回已缓存的结果
+		c.dups++
+		c.chans = append(c.chans, ch)
+		c.refMu.Lock()
+		c.refs++
+		c.refMu.Unlock()
+		g.mu.Unlock()
+		return ch, c.leave
 	}
-	c := new(call) // 创建一个新的 call 实例
-	c.wg.Add(1)    // 增加 WaitGroup 的计数
-	g.m[key] = c   // 将 call 实例存储到 map 中
-	g.mu.Unlock()  // 释放锁
 
-	// 执行函数 fn 并存储结果
-	c.val, c.err = fn()
-	c.wg.Done() // 函数执行完成，减少 WaitGroup 的计数
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &call{chans: []chan<- Result{ch}, ctx: ctx, cancel: cancel, refs: 1}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
 
-	g.mu.Lock()      // 再次加锁
-	delete(g.m, key) // 删除 map 中的 key
-	g.mu.Unlock()    // 释放锁
+	go g.doCall(c, key, fn)
+	return ch, c.leave
+}
 
-	return c.val, c.err // 返回函数的结果
+// Forget 把一个仍在进行中的调用立刻从 Group 里摘除。后续同一个 key 的 Do/DoChan
+// 调用会重新执行 fn，而不是继续等待这次（可能已经卡住的）调用；已经在等待这次
+// 调用的调用者不受影响，仍然会在它真正结束后收到结果。
+func (g *Group) Forget(key string) {
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+}
+
+// doCall 执行 fn 并把结果分发给所有等待者，负责处理 panic 和收尾清理。
+func (g *Group) doCall(c *call, key string, fn func(ctx context.Context) (interface{}, error)) {
+	defer c.cancel() // fn 正常返回后这个 Context 也就没有继续存在的必要了
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = newPanicError(r)
+			}
+		}()
+		c.val, c.err = fn(c.ctx)
+	}()
+
+	c.wg.Done()
+
+	g.mu.Lock()
+	if g.m[key] == c { // 可能已经被 Forget 摘除，这时不需要（也不能）重复删除
+		delete(g.m, key)
+	}
+	for _, ch := range c.chans {
+		ch <- Result{Val: c.val, Err: c.err, Shared: c.dups > 0}
+	}
+	g.mu.Unlock()
 }
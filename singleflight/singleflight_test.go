@@ -0,0 +1,202 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDoRepanics 验证 fn 内部的 panic 会在 Do 自己的调用栈里被重新 panic，
+// 而不是被当成普通 error 吞掉。
+func TestDoRepanics(t *testing.T) {
+	var g Group
+	defer func() {
+		r := recover()
+		pe, ok := r.(*panicError)
+		if !ok || pe.value != "boom" {
+			t.Fatalf("recover() = %#v, want a *panicError wrapping %q", r, "boom")
+		}
+	}()
+	g.Do("key", func() (interface{}, error) {
+		panic("boom")
+	})
+	t.Fatal("Do should have panicked")
+}
+
+// TestDoChanDoesNotRepanic 验证和 Do 不同，DoChan 把 fn 的 panic 包装成一个
+// 普通的 Result.Err 交给调用方，而不会在任何 goroutine 里重新 panic。
+func TestDoChanDoesNotRepanic(t *testing.T) {
+	var g Group
+	ch := g.DoChan("key", func() (interface{}, error) {
+		panic("boom")
+	})
+
+	select {
+	case r := <-ch:
+		if r.Err == nil {
+			t.Fatal("expected Result.Err to carry the panic, got nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("DoChan did not deliver a result in time")
+	}
+}
+
+func TestDoDedupsConcurrentCalls(t *testing.T) {
+	var g Group
+	var calls int32
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := g.Do("key", func() (interface{}, error) {
+				mu.Lock()
+				calls++
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				return "bar", nil
+			})
+			if err != nil {
+				t.Errorf("Do() error = %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+	for i, v := range results {
+		if v != "bar" {
+			t.Errorf("results[%d] = %v, want %q", i, v, "bar")
+		}
+	}
+}
+
+func TestDoChan(t *testing.T) {
+	var g Group
+	ch := g.DoChan("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+
+	select {
+	case r := <-ch:
+		if r.Err != nil || r.Val != "bar" {
+			t.Fatalf("got %+v, want Val=bar Err=nil", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("DoChan did not deliver a result in time")
+	}
+}
+
+func TestForgetAllowsImmediateRecall(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	go g.Do("key", func() (interface{}, error) {
+		close(started)
+		<-unblock
+		return "first", nil
+	})
+	<-started
+
+	g.Forget("key")
+
+	v, err := g.Do("key", func() (interface{}, error) {
+		return "second", nil
+	})
+	if err != nil || v != "second" {
+		t.Fatalf("Do() after Forget = (%v, %v), want (second, nil)", v, err)
+	}
+	close(unblock)
+}
+
+// TestDoChanContextCancelsAfterAllLeave 验证只有当所有共享者都调用过 leave 之后，
+// 传给 fn 的 Context 才会被取消。
+func TestDoChanContextCancelsAfterAllLeave(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+
+	ch1, leave1 := g.DoChanContext("key", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+
+	_, leave2 := g.DoChanContext("key", func(context.Context) (interface{}, error) {
+		return nil, nil
+	})
+
+	leave1()
+	select {
+	case <-ch1:
+		t.Fatal("call was canceled after only one of two participants left")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	leave2()
+	select {
+	case r := <-ch1:
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", r.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("call was not canceled after all participants left")
+	}
+}
+
+// TestDoJoinerNotCanceledByOtherLeaving 覆盖 Do 加入一个已有调用、而另一个通过
+// DoChanContext 加入的调用者提前 leave 的场景：Do 自己并未请求取消，
+// 不应该因为别人提前离开就拿到一个 context canceled 的结果。
+func TestDoJoinerNotCanceledByOtherLeaving(t *testing.T) {
+	var g Group
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	go g.Do("key", func() (interface{}, error) {
+		close(started)
+		<-unblock
+		return "done", nil
+	})
+	<-started
+
+	_, leave := g.DoChanContext("key", func(context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	leave() // 这个参与者提前放弃，不应该影响下面仍在同步等待的 Do 调用
+
+	doDone := make(chan struct{})
+	var val interface{}
+	var err error
+	var joinedNewCall bool
+	go func() {
+		val, err = g.Do("key", func() (interface{}, error) {
+			joinedNewCall = true
+			return nil, nil
+		})
+		close(doDone)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(unblock)
+
+	select {
+	case <-doDone:
+		if joinedNewCall {
+			t.Fatal("should have joined the in-flight call instead of starting a new one")
+		}
+		if err != nil || val != "done" {
+			t.Fatalf("Do() = (%v, %v), want (done, nil)", val, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Do() never returned")
+	}
+}